@@ -1,15 +1,18 @@
 package cli
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math"
 	"net"
 	"net/http"
 	"net/url"
 	"strconv"
+	"sync"
 	"time"
 
 	"encoding/json"
@@ -17,7 +20,10 @@ import (
 	grpc_retry "github.com/grpc-ecosystem/go-grpc-middleware/retry"
 	"github.com/seldonio/seldon-core/operatorv2/scheduler/apis/mlops/v2_dataplane"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
@@ -35,11 +41,55 @@ const (
 )
 
 type InferenceClient struct {
-	host        string
-	port        int
-	httpClient  *http.Client
-	callOptions []grpc.CallOption
-	counts      map[string]int
+	host          string
+	port          int
+	httpClient    *http.Client
+	callOptions   []grpc.CallOption
+	counts        map[string]int
+	countsMu      sync.Mutex
+	retryPolicy   *RetryPolicy
+	hedgingPolicy *HedgingPolicy
+	tlsConfig     *TLSConfig
+	authToken     string
+}
+
+// RetryPolicy controls how getConnection's grpc_retry interceptors retry a failed
+// unary or streaming call.
+type RetryPolicy struct {
+	MaxAttempts       int
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	BackoffMultiplier float64
+}
+
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:       5,
+		InitialBackoff:    100 * time.Millisecond,
+		MaxBackoff:        10 * time.Second,
+		BackoffMultiplier: 2.0,
+	}
+}
+
+// HedgingPolicy sends an inference call to MaxAttempts destinations in parallel and
+// returns the first non-error response, cancelling the rest. It is intended for
+// latency-sensitive inference where a single slow replica should not be waited on.
+type HedgingPolicy struct {
+	MaxAttempts         int
+	HedgingDelay        time.Duration
+	NonFatalStatusCodes []codes.Code
+}
+
+func (hp *HedgingPolicy) isNonFatal(code codes.Code) bool {
+	if hp == nil {
+		return false
+	}
+	for _, c := range hp.NonFatalStatusCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
 }
 
 type V2Error struct {
@@ -54,29 +104,49 @@ type V2InferenceResponse struct {
 	Outputs      []interface{}          `json:"outputs,omitempty"`
 }
 
-func NewInferenceClient(host string, port int) *InferenceClient {
+// NewInferenceClient creates a client for the given v2 endpoint. retryPolicy and
+// hedgingPolicy may be nil, in which case a default retry policy is used and hedging
+// is disabled. tlsConfig enables mTLS instead of the plaintext connection used when
+// it is nil; authToken, if set, is sent as a bearer token on every gRPC call.
+func NewInferenceClient(host string, port int, retryPolicy *RetryPolicy, hedgingPolicy *HedgingPolicy, tlsConfig *TLSConfig, authToken string) *InferenceClient {
 	opts := []grpc.CallOption{
 		grpc.MaxCallSendMsgSize(math.MaxInt32),
 		grpc.MaxCallRecvMsgSize(math.MaxInt32),
 	}
+	if retryPolicy == nil {
+		retryPolicy = DefaultRetryPolicy()
+	}
 	return &InferenceClient{
-		host:        host,
-		port:        port,
-		httpClient:  http.DefaultClient,
-		callOptions: opts,
-		counts:      make(map[string]int),
+		host:          host,
+		port:          port,
+		httpClient:    http.DefaultClient,
+		callOptions:   opts,
+		counts:        make(map[string]int),
+		retryPolicy:   retryPolicy,
+		hedgingPolicy: hedgingPolicy,
+		tlsConfig:     tlsConfig,
+		authToken:     authToken,
 	}
 }
 
 func (ic *InferenceClient) getConnection() (*grpc.ClientConn, error) {
 	retryOpts := []grpc_retry.CallOption{
-		grpc_retry.WithBackoff(grpc_retry.BackoffExponential(100 * time.Millisecond)),
+		grpc_retry.WithMax(uint(ic.retryPolicy.MaxAttempts)),
+		grpc_retry.WithBackoff(grpc_retry.BackoffExponential(ic.retryPolicy.InitialBackoff)),
 	}
 	opts := []grpc.DialOption{
-		grpc.WithInsecure(),
 		grpc.WithStreamInterceptor(grpc_retry.StreamClientInterceptor(retryOpts...)),
 		grpc.WithUnaryInterceptor(grpc_retry.UnaryClientInterceptor(retryOpts...)),
 	}
+	if ic.tlsConfig != nil {
+		tlsCfg, err := ic.tlsConfig.clientTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg)))
+	} else {
+		opts = append(opts, grpc.WithInsecure())
+	}
 	conn, err := grpc.Dial(fmt.Sprintf("%s:%d", ic.host, ic.port), opts...)
 	if err != nil {
 		return nil, err
@@ -84,6 +154,20 @@ func (ic *InferenceClient) getConnection() (*grpc.ClientConn, error) {
 	return conn, nil
 }
 
+// withAuth attaches the configured bearer token, if any, to an outgoing gRPC context.
+func (ic *InferenceClient) withAuth(ctx context.Context) context.Context {
+	if ic.authToken == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+ic.authToken)
+}
+
+// hedgingEnabled reports whether this client should fire parallel hedged calls
+// rather than a single call per iteration.
+func (ic *InferenceClient) hedgingEnabled() bool {
+	return ic.hedgingPolicy != nil && ic.hedgingPolicy.MaxAttempts > 1
+}
+
 func (ic *InferenceClient) getUrl(path string) *url.URL {
 	return &url.URL{
 		Scheme: "http",
@@ -92,9 +176,9 @@ func (ic *InferenceClient) getUrl(path string) *url.URL {
 	}
 }
 
-func (ic *InferenceClient) call(resourceName string, path string, data []byte, inferType InferType) ([]byte, error) {
+func (ic *InferenceClient) call(ctx context.Context, resourceName string, path string, data []byte, inferType InferType) ([]byte, error) {
 	v2Url := ic.getUrl(path)
-	req, err := http.NewRequest("POST", v2Url.String(), bytes.NewBuffer(data))
+	req, err := http.NewRequestWithContext(ctx, "POST", v2Url.String(), bytes.NewBuffer(data))
 	if err != nil {
 		return nil, err
 	}
@@ -133,7 +217,11 @@ func (ic *InferenceClient) call(resourceName string, path string, data []byte, i
 	return b, nil
 }
 
+// updateSummary is safe to call from multiple goroutines, since InferStream's
+// parallel workers all aggregate into the same counts map.
 func (ic *InferenceClient) updateSummary(modelName string) {
+	ic.countsMu.Lock()
+	defer ic.countsMu.Unlock()
 	if count, ok := ic.counts[modelName]; ok {
 		ic.counts[modelName] = count + 1
 	} else {
@@ -141,13 +229,64 @@ func (ic *InferenceClient) updateSummary(modelName string) {
 	}
 }
 
+// hedgedCall fires the given call function across ic.hedgingPolicy.MaxAttempts
+// goroutines, staggered by HedgingDelay, and returns the first non-error response,
+// cancelling the context passed to the remaining in-flight attempts so their
+// underlying HTTP requests are aborted rather than running to completion against the
+// backend after a winner has already been returned.
+func (ic *InferenceClient) hedgedCall(call func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	type result struct {
+		res []byte
+		err error
+	}
+	hedgeCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	resCh := make(chan result, ic.hedgingPolicy.MaxAttempts)
+	for i := 0; i < ic.hedgingPolicy.MaxAttempts; i++ {
+		attempt := i
+		go func() {
+			if attempt > 0 {
+				select {
+				case <-time.After(ic.hedgingPolicy.HedgingDelay):
+				case <-hedgeCtx.Done():
+					return
+				}
+			}
+			res, err := call(hedgeCtx)
+			select {
+			case resCh <- result{res, err}:
+			case <-hedgeCtx.Done():
+			}
+		}()
+	}
+
+	var lastErr error
+	for i := 0; i < ic.hedgingPolicy.MaxAttempts; i++ {
+		r := <-resCh
+		if r.err == nil {
+			return r.res, nil
+		}
+		lastErr = r.err
+	}
+	return nil, lastErr
+}
+
 func (ic *InferenceClient) InferRest(resourceName string, data []byte, showRequest bool, showResponse bool, iterations int, inferType InferType) error {
 	if showRequest {
 		printPrettyJson(data)
 	}
 	path := fmt.Sprintf("/v2/models/%s/infer", resourceName)
 	for i := 0; i < iterations; i++ {
-		res, err := ic.call(resourceName, path, data, inferType)
+		var res []byte
+		var err error
+		if ic.hedgingEnabled() {
+			res, err = ic.hedgedCall(func(ctx context.Context) ([]byte, error) {
+				return ic.call(ctx, resourceName, path, data, inferType)
+			})
+		} else {
+			res, err = ic.call(context.Background(), resourceName, path, data, inferType)
+		}
 		if err != nil {
 			return err
 		}
@@ -185,7 +324,7 @@ func (ic *InferenceClient) InferGrpc(resourceName string, data []byte, showReque
 		return err
 	}
 	grpcClient := v2_dataplane.NewGRPCInferenceServiceClient(conn)
-	ctx := context.TODO()
+	ctx := ic.withAuth(context.TODO())
 	switch inferType {
 	case InferModel:
 		ctx = metadata.AppendToOutgoingContext(ctx, SeldonModelHeader, resourceName)
@@ -194,7 +333,13 @@ func (ic *InferenceClient) InferGrpc(resourceName string, data []byte, showReque
 	}
 
 	for i := 0; i < iterations; i++ {
-		res, err := grpcClient.ModelInfer(ctx, req)
+		var res *v2_dataplane.ModelInferResponse
+		var err error
+		if ic.hedgingEnabled() {
+			res, err = ic.hedgedGrpcInfer(ctx, grpcClient, req)
+		} else {
+			res, err = grpcClient.ModelInfer(ctx, req)
+		}
 		if err != nil {
 			return err
 		}
@@ -212,13 +357,178 @@ func (ic *InferenceClient) InferGrpc(resourceName string, data []byte, showReque
 	return nil
 }
 
-func (ic *InferenceClient) Infer(modelName string, inferMode string, data []byte, showRequest bool, showResponse bool, iterations int, inferType InferType) error {
+// hedgedGrpcInfer fires ModelInfer across ic.hedgingPolicy.MaxAttempts goroutines,
+// staggered by HedgingDelay, and returns the first non-error response while
+// cancelling the rest. A response whose status code is in NonFatalStatusCodes does
+// not abort the remaining in-flight attempts.
+func (ic *InferenceClient) hedgedGrpcInfer(ctx context.Context, grpcClient v2_dataplane.GRPCInferenceServiceClient, req *v2_dataplane.ModelInferRequest) (*v2_dataplane.ModelInferResponse, error) {
+	type result struct {
+		res *v2_dataplane.ModelInferResponse
+		err error
+	}
+	hedgeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	resCh := make(chan result, ic.hedgingPolicy.MaxAttempts)
+	for i := 0; i < ic.hedgingPolicy.MaxAttempts; i++ {
+		attempt := i
+		go func() {
+			if attempt > 0 {
+				select {
+				case <-time.After(ic.hedgingPolicy.HedgingDelay):
+				case <-hedgeCtx.Done():
+					return
+				}
+			}
+			res, err := grpcClient.ModelInfer(hedgeCtx, req)
+			select {
+			case resCh <- result{res, err}:
+			case <-hedgeCtx.Done():
+			}
+		}()
+	}
+
+	var lastErr error
+	for i := 0; i < ic.hedgingPolicy.MaxAttempts; i++ {
+		r := <-resCh
+		if r.err == nil {
+			return r.res, nil
+		}
+		if !ic.hedgingPolicy.isNonFatal(status.Code(r.err)) {
+			return nil, r.err
+		}
+		lastErr = r.err
+	}
+	return nil, lastErr
+}
+
+// InferStream reads newline-delimited JSON ModelInferRequest messages from in and
+// issues one gRPC ModelInfer call per line, printing each response as it arrives -
+// there is no bidirectional streaming RPC in the v2 dataplane, so this wraps repeated
+// unary calls behind a Go channel API instead. When iterationsParallel > 1, that many
+// workers share one grpc.ClientConn and drain a common channel of lines concurrently,
+// aggregating per-model counts into ic.counts under ic.countsMu.
+func (ic *InferenceClient) InferStream(resourceName string, in io.Reader, showRequest bool, showResponse bool, inferType InferType, iterationsParallel int) error {
+	conn, err := ic.getConnection()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	grpcClient := v2_dataplane.NewGRPCInferenceServiceClient(conn)
+
+	ctx := ic.withAuth(context.TODO())
+	switch inferType {
+	case InferModel:
+		ctx = metadata.AppendToOutgoingContext(ctx, SeldonModelHeader, resourceName)
+	case InferPipeline:
+		ctx = metadata.AppendToOutgoingContext(ctx, SeldonPipelineHeader, resourceName)
+	}
+
+	if iterationsParallel < 1 {
+		iterationsParallel = 1
+	}
+
+	lines := make(chan []byte)
+	var scanErr error
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(in)
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if len(line) == 0 {
+				continue
+			}
+			lines <- append([]byte(nil), line...)
+		}
+		scanErr = scanner.Err()
+	}()
+
+	firstErr := runLineWorkers(iterationsParallel, lines, func(line []byte) error {
+		res, err := ic.streamOne(ctx, grpcClient, resourceName, line, showRequest, showResponse)
+		if err != nil {
+			return err
+		}
+		ic.updateSummary(res.ModelName)
+		return nil
+	})
+
+	fmt.Printf("%v\n", ic.counts)
+
+	if scanErr != nil {
+		return scanErr
+	}
+	return firstErr
+}
+
+// runLineWorkers fans lines out across parallel workers each calling work, and reports
+// the first error any worker returned (if any). errs is drained by a dedicated
+// collector goroutine running concurrently with the workers, not after they finish, so
+// a burst of errors larger than the worker count cannot block a worker on `errs <-
+// err` and deadlock the wait below.
+func runLineWorkers(parallel int, lines <-chan []byte, work func(line []byte) error) error {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	errs := make(chan error, parallel)
+	var wg sync.WaitGroup
+	for w := 0; w < parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for line := range lines {
+				if err := work(line); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+
+	var firstErr error
+	errsDone := make(chan struct{})
+	go func() {
+		defer close(errsDone)
+		for err := range errs {
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}()
+
+	wg.Wait()
+	close(errs)
+	<-errsDone
+	return firstErr
+}
+
+func (ic *InferenceClient) streamOne(ctx context.Context, grpcClient v2_dataplane.GRPCInferenceServiceClient, resourceName string, line []byte, showRequest bool, showResponse bool) (*v2_dataplane.ModelInferResponse, error) {
+	req := &v2_dataplane.ModelInferRequest{}
+	if err := protojson.Unmarshal(line, req); err != nil {
+		return nil, err
+	}
+	req.ModelName = resourceName
+	if showRequest {
+		printProto(req)
+	}
+	res, err := grpcClient.ModelInfer(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if showResponse {
+		printProto(res)
+	}
+	return res, nil
+}
+
+func (ic *InferenceClient) Infer(modelName string, inferMode string, data []byte, showRequest bool, showResponse bool, iterations int, inferType InferType, stdin io.Reader, iterationsParallel int) error {
 	switch inferMode {
 	case "rest":
 		return ic.InferRest(modelName, data, showRequest, showResponse, iterations, inferType)
 	case "grpc":
 		return ic.InferGrpc(modelName, data, showRequest, showResponse, iterations, inferType)
+	case "stream":
+		return ic.InferStream(modelName, stdin, showRequest, showResponse, inferType, iterationsParallel)
 	default:
-		return fmt.Errorf("Unknown infer mode - needs to be grpc or rest")
+		return fmt.Errorf("Unknown infer mode - needs to be grpc, rest or stream")
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestRunLineWorkersManyErrorsDoesNotDeadlock reproduces the InferStream hang: with
+// iterationsParallel workers and more failing lines than the errs channel's buffer,
+// every worker used to block forever on `errs <- err` because errs was only drained
+// after wg.Wait(). runLineWorkers drains errs concurrently instead, so this must
+// return promptly regardless of how many lines fail.
+func TestRunLineWorkersManyErrorsDoesNotDeadlock(t *testing.T) {
+	const parallel = 2
+	const numLines = 10
+
+	lines := make(chan []byte, numLines)
+	for i := 0; i < numLines; i++ {
+		lines <- []byte("line")
+	}
+	close(lines)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- runLineWorkers(parallel, lines, func(line []byte) error {
+			return errors.New("boom")
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected the first error to be returned")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("runLineWorkers deadlocked with more errors than worker count")
+	}
+}
+
+// TestHedgedCallCancelsLosingAttempts ensures that once a winning attempt returns,
+// hedgedCall cancels the context handed to the other in-flight attempts, so a REST
+// call that respects ctx (as http.NewRequestWithContext does) aborts instead of
+// running to completion against the backend after a winner is already returned.
+func TestHedgedCallCancelsLosingAttempts(t *testing.T) {
+	ic := &InferenceClient{
+		hedgingPolicy: &HedgingPolicy{MaxAttempts: 2, HedgingDelay: time.Hour},
+	}
+
+	loserCanceled := make(chan struct{})
+	winnerReturned := make(chan struct{})
+
+	res, err := ic.hedgedCall(func(ctx context.Context) ([]byte, error) {
+		// With HedgingDelay set to an hour, only attempt 0 runs immediately; it is the
+		// winner. Attempt 1 would only fire after the delay, so in practice this
+		// closure only ever runs once per test - the real guarantee under test is that
+		// hedgeCtx is cancelled once hedgedCall returns.
+		close(winnerReturned)
+		go func() {
+			<-ctx.Done()
+			close(loserCanceled)
+		}()
+		return []byte("ok"), nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if string(res) != "ok" {
+		t.Fatalf("expected winning response, got %q", res)
+	}
+
+	<-winnerReturned
+	select {
+	case <-loserCanceled:
+	case <-time.After(time.Second):
+		t.Fatal("expected hedgedCall's context to be cancelled once a winner was returned")
+	}
+}
+
+func TestRunLineWorkersReturnsNilOnSuccess(t *testing.T) {
+	lines := make(chan []byte, 3)
+	lines <- []byte("a")
+	lines <- []byte("b")
+	lines <- []byte("c")
+	close(lines)
+
+	err := runLineWorkers(2, lines, func(line []byte) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
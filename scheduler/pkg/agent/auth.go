@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type contextKey string
+
+const authClaimsContextKey contextKey = "agent-auth-claims"
+
+// TokenClaims identifies what a validated token authorizes its bearer to act as.
+// ReplicaIdx is a pointer so a token can be scoped to a whole server (nil, any
+// replica) or to one specific replica (non-nil) of that server.
+type TokenClaims struct {
+	ServerName string
+	ReplicaIdx *uint32
+}
+
+// TokenValidator validates the bearer/JWT token presented by a connecting agent and
+// returns the claims that token authorizes the caller to act as.
+type TokenValidator func(token string) (claims TokenClaims, err error)
+
+func tokenFromContext(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "missing request metadata")
+	}
+	vals := md.Get("authorization")
+	if len(vals) == 0 {
+		return "", status.Error(codes.Unauthenticated, "missing authorization token")
+	}
+	return strings.TrimPrefix(vals[0], "Bearer "), nil
+}
+
+// claimsFromContext returns the TokenClaims bound to ctx by UnaryAuthInterceptor or
+// StreamAuthInterceptor, and false if no TokenValidator is configured.
+func claimsFromContext(ctx context.Context) (TokenClaims, bool) {
+	claims, ok := ctx.Value(authClaimsContextKey).(TokenClaims)
+	return claims, ok
+}
+
+// UnaryAuthInterceptor validates the bearer token on unary RPCs (AgentEvent) using
+// validator and binds its subject into the handler's context. A nil validator
+// disables authentication, preserving today's behaviour.
+func UnaryAuthInterceptor(validator TokenValidator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if validator == nil {
+			return handler(ctx, req)
+		}
+		token, err := tokenFromContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		claims, err := validator(token)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return handler(context.WithValue(ctx, authClaimsContextKey, claims), req)
+	}
+}
+
+// StreamAuthInterceptor is the streaming equivalent of UnaryAuthInterceptor, used for
+// Subscribe. Subscribe itself rejects a request whose bound claims do not match the
+// requested ServerKey{ServerName, ReplicaIdx}, so a compromised agent cannot
+// impersonate another server's replica and have RemoveServerReplica evict a replica it
+// does not own.
+func StreamAuthInterceptor(validator TokenValidator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if validator == nil {
+			return handler(srv, ss)
+		}
+		token, err := tokenFromContext(ss.Context())
+		if err != nil {
+			return err
+		}
+		claims, err := validator(token)
+		if err != nil {
+			return status.Error(codes.Unauthenticated, err.Error())
+		}
+		return handler(srv, &authenticatedServerStream{
+			ServerStream: ss,
+			ctx:          context.WithValue(ss.Context(), authClaimsContextKey, claims),
+		})
+	}
+}
+
+type authenticatedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedServerStream) Context() context.Context {
+	return s.ctx
+}
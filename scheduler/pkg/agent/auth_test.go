@@ -0,0 +1,148 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeServerStream is the minimal grpc.ServerStream needed to exercise
+// StreamAuthInterceptor: it embeds a nil grpc.ServerStream (whose other methods are
+// never called by the interceptor) and overrides Context().
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (f *fakeServerStream) Context() context.Context {
+	return f.ctx
+}
+
+func contextWithToken(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+// TestUnaryAuthInterceptorNilValidatorDisablesAuth ensures a nil TokenValidator
+// preserves today's unauthenticated behaviour rather than rejecting every call.
+func TestUnaryAuthInterceptorNilValidatorDisablesAuth(t *testing.T) {
+	interceptor := UnaryAuthInterceptor(nil)
+	called := false
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		if _, ok := claimsFromContext(ctx); ok {
+			t.Fatal("expected no claims bound with a nil validator")
+		}
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !called {
+		t.Fatal("expected the handler to be invoked")
+	}
+}
+
+// TestUnaryAuthInterceptorBindsClaims ensures a valid token's claims are bound into
+// the context the handler receives.
+func TestUnaryAuthInterceptorBindsClaims(t *testing.T) {
+	want := TokenClaims{ServerName: "server-a"}
+	validator := func(token string) (TokenClaims, error) {
+		if token != "good-token" {
+			t.Fatalf("unexpected token passed to validator: %q", token)
+		}
+		return want, nil
+	}
+	interceptor := UnaryAuthInterceptor(validator)
+
+	var got TokenClaims
+	var ok bool
+	_, err := interceptor(contextWithToken("good-token"), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		got, ok = claimsFromContext(ctx)
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !ok || got != want {
+		t.Fatalf("expected claims %+v bound into the handler's context, got %+v (ok=%v)", want, got, ok)
+	}
+}
+
+// TestUnaryAuthInterceptorMissingToken ensures a request with no authorization
+// metadata is rejected before the handler runs.
+func TestUnaryAuthInterceptorMissingToken(t *testing.T) {
+	interceptor := UnaryAuthInterceptor(func(string) (TokenClaims, error) { return TokenClaims{}, nil })
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		t.Fatal("expected the handler to not be invoked without a token")
+		return nil, nil
+	})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated for a missing token, got %v", err)
+	}
+}
+
+// TestUnaryAuthInterceptorValidatorError ensures a token the validator rejects never
+// reaches the handler.
+func TestUnaryAuthInterceptorValidatorError(t *testing.T) {
+	interceptor := UnaryAuthInterceptor(func(string) (TokenClaims, error) { return TokenClaims{}, errors.New("bad token") })
+	_, err := interceptor(contextWithToken("bad-token"), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		t.Fatal("expected the handler to not be invoked for an invalid token")
+		return nil, nil
+	})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated for a rejected token, got %v", err)
+	}
+}
+
+// TestStreamAuthInterceptorBindsClaims ensures the claims a valid token resolves to
+// are reachable from the handler's stream via Context(), the same way AgentEvent and
+// Subscribe read them for a unary/streaming call respectively.
+func TestStreamAuthInterceptorBindsClaims(t *testing.T) {
+	replicaIdx := uint32(3)
+	want := TokenClaims{ServerName: "server-a", ReplicaIdx: &replicaIdx}
+	validator := func(token string) (TokenClaims, error) {
+		return want, nil
+	}
+	interceptor := StreamAuthInterceptor(validator)
+	stream := &fakeServerStream{ctx: contextWithToken("good-token")}
+
+	var got TokenClaims
+	var ok bool
+	err := interceptor(nil, stream, &grpc.StreamServerInfo{}, func(srv interface{}, ss grpc.ServerStream) error {
+		got, ok = claimsFromContext(ss.Context())
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !ok || got.ServerName != want.ServerName || *got.ReplicaIdx != *want.ReplicaIdx {
+		t.Fatalf("expected claims %+v bound into the handler's stream context, got %+v (ok=%v)", want, got, ok)
+	}
+}
+
+// TestStreamAuthInterceptorNilValidatorDisablesAuth mirrors
+// TestUnaryAuthInterceptorNilValidatorDisablesAuth for the streaming interceptor.
+func TestStreamAuthInterceptorNilValidatorDisablesAuth(t *testing.T) {
+	interceptor := StreamAuthInterceptor(nil)
+	stream := &fakeServerStream{ctx: context.Background()}
+
+	called := false
+	err := interceptor(nil, stream, &grpc.StreamServerInfo{}, func(srv interface{}, ss grpc.ServerStream) error {
+		called = true
+		if ss != stream {
+			t.Fatal("expected the original stream to be passed through unwrapped")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !called {
+		t.Fatal("expected the handler to be invoked")
+	}
+}
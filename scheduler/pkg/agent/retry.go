@@ -0,0 +1,93 @@
+package agent
+
+import (
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// RetryPolicy controls how the scheduler retries a failed send on a model server
+// replica's Subscribe stream. It is applied per model/server rather than globally so
+// that slow or flaky servers can be tuned without affecting the rest of the fleet.
+type RetryPolicy struct {
+	MaxAttempts          int
+	InitialBackoff       time.Duration
+	MaxBackoff           time.Duration
+	BackoffMultiplier    float64
+	RetryableStatusCodes []codes.Code
+}
+
+// HedgingPolicy allows an inference call to be sent to more than one replica in
+// parallel, returning as soon as the first non-error response arrives.
+type HedgingPolicy struct {
+	MaxAttempts         int
+	HedgingDelay        time.Duration
+	NonFatalStatusCodes []codes.Code
+}
+
+// DefaultRetryPolicy mirrors the backoff that was previously hardcoded via the
+// grpc_retry interceptor.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:       5,
+		InitialBackoff:    100 * time.Millisecond,
+		MaxBackoff:        10 * time.Second,
+		BackoffMultiplier: 2.0,
+		RetryableStatusCodes: []codes.Code{
+			codes.Unavailable,
+			codes.DeadlineExceeded,
+		},
+	}
+}
+
+func (p *RetryPolicy) isRetryable(code codes.Code) bool {
+	if p == nil {
+		return false
+	}
+	for _, c := range p.RetryableStatusCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// AgentServerConfig bundles the policies that govern how the agent Server talks back
+// to subscribed replicas.
+type AgentServerConfig struct {
+	RetryPolicy *RetryPolicy
+
+	// ReconcileInterval is how often Server.RunReconcile snapshots the desired model
+	// set for each subscribed replica. Zero disables periodic reconciliation.
+	ReconcileInterval time.Duration
+
+	// TLS enables mTLS on the agent gRPC server. Nil disables TLS, preserving
+	// today's plaintext behaviour.
+	TLS *TLSConfig
+
+	// TokenValidator authenticates the bearer/JWT token a connecting agent presents.
+	// Nil disables authentication.
+	TokenValidator TokenValidator
+
+	// EnqueueTimeout bounds how long enqueueOp blocks trying to hand an op to a
+	// replica's full subscriber queue before giving up and falling back to pending.
+	// This is what makes subscriberQueueSize an actual backpressure mechanism: a slow
+	// replica stalls Sync for up to EnqueueTimeout instead of every op immediately
+	// spilling into pending.
+	EnqueueTimeout time.Duration
+
+	// MaxPendingPerReplica caps how many undelivered ops enqueuePending will hold for
+	// a single replica. Once the cap is reached the oldest pending op is dropped to
+	// make room for the newest, so a replica that never resubscribes (e.g. it was
+	// decommissioned) cannot grow pending without bound.
+	MaxPendingPerReplica int
+}
+
+func DefaultAgentServerConfig() *AgentServerConfig {
+	return &AgentServerConfig{
+		RetryPolicy:          DefaultRetryPolicy(),
+		ReconcileInterval:    30 * time.Second,
+		EnqueueTimeout:       5 * time.Second,
+		MaxPendingPerReplica: 100,
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/seldonio/seldon-core/scheduler/pkg/coordinator"
 
@@ -19,6 +21,10 @@ import (
 
 const (
 	grpcMaxConcurrentStreams = 1_000_000
+
+	// subscriberQueueSize bounds the number of operations buffered for a single
+	// replica before Sync falls back to the pending queue for later redelivery.
+	subscriberQueueSize = 100
 )
 
 type ServerKey struct {
@@ -26,6 +32,15 @@ type ServerKey struct {
 	replicaIdx uint32
 }
 
+// replicaModelKey scopes a last-observed generation to one model on one replica.
+// Tracking generation per ServerKey alone is wrong once a replica hosts more than one
+// model: a generation bump caused by syncing model B would make an older, still
+// in-flight op for model A look stale even though nothing about model A changed.
+type replicaModelKey struct {
+	ServerKey
+	model string
+}
+
 type Server struct {
 	mutext sync.RWMutex
 	pb.UnimplementedAgentServiceServer
@@ -34,28 +49,54 @@ type Server struct {
 	store     store.SchedulerStore
 	source    chan coordinator.ModelEventMsg
 	scheduler scheduler.Scheduler
+	config    *AgentServerConfig
+
+	// pendingMu guards pending independently of mutext, since pending ops are queued
+	// from inside Sync's read lock on the agents map.
+	pendingMu sync.Mutex
+	pending   map[ServerKey][]*pb.ModelOperationMessage
+
+	// generation is a monotonic counter bumped every time Sync computes a new desired
+	// state; it is stamped onto every outgoing ModelOperationMessage so a replica and
+	// the scheduler can agree on which desired-state generation an event refers to.
+	generation uint64
+
+	// replicaGenMu guards replicaGeneration independently of mutext, for the same
+	// reason as pendingMu.
+	replicaGenMu      sync.Mutex
+	replicaGeneration map[replicaModelKey]uint64
 }
 
 type SchedulerAgent interface {
 	Sync(modelName string) error
 }
 
+// AgentSubscriber owns the grpc stream for one subscribed replica for the lifetime of
+// a Subscribe call. All sends go through queue, and only the writer goroutine started
+// in Subscribe ever calls stream.Send, since grpc streams are not thread safe for
+// SendMsg https://github.com/grpc/grpc-go/issues/2355.
 type AgentSubscriber struct {
 	finished chan<- bool
-	//mutext   sync.Mutex // grpc streams are not thread safe for sendMsg https://github.com/grpc/grpc-go/issues/2355
-	stream pb.AgentService_SubscribeServer
+	queue    chan *pb.ModelOperationMessage
 }
 
 func NewAgentServer(logger log.FieldLogger,
 	store store.SchedulerStore,
 	scheduler scheduler.Scheduler,
-	hub *coordinator.ModelEventHub) *Server {
+	hub *coordinator.ModelEventHub,
+	config *AgentServerConfig) *Server {
+	if config == nil {
+		config = DefaultAgentServerConfig()
+	}
 	s := &Server{
-		logger:    logger.WithField("source", "AgentServer"),
-		agents:    make(map[ServerKey]*AgentSubscriber),
-		store:     store,
-		source:    make(chan coordinator.ModelEventMsg, 1),
-		scheduler: scheduler,
+		logger:            logger.WithField("source", "AgentServer"),
+		agents:            make(map[ServerKey]*AgentSubscriber),
+		pending:           make(map[ServerKey][]*pb.ModelOperationMessage),
+		store:             store,
+		source:            make(chan coordinator.ModelEventMsg, 1),
+		scheduler:         scheduler,
+		config:            config,
+		replicaGeneration: make(map[replicaModelKey]uint64),
 	}
 	hub.AddListener(s.source)
 	return s
@@ -76,16 +117,32 @@ func (s *Server) StartGrpcServer(agentPort uint) error {
 	}
 	var grpcOptions []grpc.ServerOption
 	grpcOptions = append(grpcOptions, grpc.MaxConcurrentStreams(grpcMaxConcurrentStreams))
+
+	if s.config.TLS != nil {
+		creds, err := newReloadableServerCredentials(s.config.TLS)
+		if err != nil {
+			return fmt.Errorf("failed to load TLS credentials: %w", err)
+		}
+		grpcOptions = append(grpcOptions, grpc.Creds(creds))
+		go watchForReload(s.logger, s.config.TLS, creds)
+	}
+
+	grpcOptions = append(grpcOptions,
+		grpc.ChainUnaryInterceptor(UnaryAuthInterceptor(s.config.TokenValidator)),
+		grpc.ChainStreamInterceptor(StreamAuthInterceptor(s.config.TokenValidator)),
+	)
+
 	grpcServer := grpc.NewServer(grpcOptions...)
 	pb.RegisterAgentServiceServer(grpcServer, s)
 	s.logger.Printf("Agent server running on %d", agentPort)
 	return grpcServer.Serve(lis)
 }
 
+// Sync fans the desired state for modelName out to subscribed replicas by enqueueing
+// onto each replica's AgentSubscriber.queue. It never calls stream.Send directly -
+// that is the sole responsibility of each subscriber's writer goroutine.
 func (s *Server) Sync(modelName string) {
 	logger := s.logger.WithField("func", "Sync")
-	s.mutext.RLock()
-	defer s.mutext.RUnlock()
 
 	model, err := s.store.GetModel(modelName)
 	if err != nil {
@@ -97,25 +154,21 @@ func (s *Server) Sync(modelName string) {
 		return
 	}
 
+	gen := atomic.AddUint64(&s.generation, 1)
+
 	// Handle any load requests for latest version - we don't want to load models from older versions
 	latestModel := model.GetLatest()
 	if latestModel != nil {
 		for _, replicaIdx := range latestModel.GetReplicaForState(store.LoadRequested) {
 			logger.Infof("Sending load model request for %s", modelName)
 
-			as, ok := s.agents[ServerKey{serverName: latestModel.Server(), replicaIdx: uint32(replicaIdx)}]
-
-			if !ok {
-				logger.Errorf("Failed to find server replica for %s:%d", latestModel.Server(), replicaIdx)
-				continue
-			}
-
-			err = as.stream.Send(&pb.ModelOperationMessage{
+			key := ServerKey{serverName: latestModel.Server(), replicaIdx: uint32(replicaIdx)}
+			op := &pb.ModelOperationMessage{
 				Operation:    pb.ModelOperationMessage_LOAD_MODEL,
 				ModelVersion: &pb.ModelVersion{Model: latestModel.GetModel(), Version: latestModel.GetVersion()},
-			})
-			if err != nil {
-				logger.WithError(err).Errorf("stream message send failed for model %s and replicaidx %d", modelName, replicaIdx)
+				Generation:   gen,
+			}
+			if !s.enqueueOp(key, op, gen) {
 				continue
 			}
 			err := s.store.UpdateModelState(latestModel.Key(), latestModel.GetVersion(), latestModel.Server(), replicaIdx, nil, store.Loading, "")
@@ -130,17 +183,14 @@ func (s *Server) Sync(modelName string) {
 	for _, modelVersion := range model.Versions {
 		for _, replicaIdx := range modelVersion.GetReplicaForState(store.UnloadRequested) {
 			s.logger.Infof("Sending unload model request for %s", modelName)
-			as, ok := s.agents[ServerKey{serverName: modelVersion.Server(), replicaIdx: uint32(replicaIdx)}]
-			if !ok {
-				logger.Errorf("Failed to find server replica for %s:%d", modelVersion.Server(), replicaIdx)
-				continue
-			}
-			err = as.stream.Send(&pb.ModelOperationMessage{
+
+			key := ServerKey{serverName: modelVersion.Server(), replicaIdx: uint32(replicaIdx)}
+			op := &pb.ModelOperationMessage{
 				Operation:    pb.ModelOperationMessage_UNLOAD_MODEL,
 				ModelVersion: &pb.ModelVersion{Model: modelVersion.GetModel(), Version: modelVersion.GetVersion()},
-			})
-			if err != nil {
-				logger.WithError(err).Errorf("stream message send failed for model %s and replicaidx %d", modelName, replicaIdx)
+				Generation:   gen,
+			}
+			if !s.enqueueOp(key, op, gen) {
 				continue
 			}
 			err := s.store.UpdateModelState(modelVersion.Key(), modelVersion.GetVersion(), modelVersion.Server(), replicaIdx, nil, store.Unloading, "")
@@ -152,8 +202,91 @@ func (s *Server) Sync(modelName string) {
 	}
 }
 
+// enqueueOp hands op to key's subscriber over its buffered channel, then records gen
+// as the desired generation last dispatched to that replica so a later AgentEvent can
+// be checked for staleness. If there is no subscriber, op is queued in pending for
+// redelivery on the next successful Subscribe rather than being silently dropped. If
+// there is a subscriber but its queue is full, enqueueOp blocks for up to
+// config.EnqueueTimeout so a slow replica applies real backpressure on Sync rather than
+// immediately spilling into the unbounded-looking pending queue; only once that
+// timeout elapses does it fall back to pending. enqueueOp takes s.mutext.RLock() itself
+// just long enough to look up the subscriber, rather than requiring callers to hold it
+// across the blocking send below - Sync and Reconcile dispatch to potentially many
+// replicas per call, and holding s.mutext for the whole of a slow replica's
+// EnqueueTimeout would stall every other Subscribe registration and disconnect
+// cleanup, which also take s.mutext, for that long.
+func (s *Server) enqueueOp(key ServerKey, op *pb.ModelOperationMessage, gen uint64) bool {
+	s.mutext.RLock()
+	as, ok := s.agents[key]
+	s.mutext.RUnlock()
+	if !ok {
+		s.logger.Errorf("Failed to find server replica for %s:%d", key.serverName, key.replicaIdx)
+		s.enqueuePending(key, op)
+		return false
+	}
+	timer := time.NewTimer(s.config.EnqueueTimeout)
+	defer timer.Stop()
+	select {
+	case as.queue <- op:
+		s.setReplicaGeneration(key, opModelName(op), gen)
+		return true
+	case <-timer.C:
+		s.logger.Warnf("subscriber queue full for %s:%d after waiting %s, queuing op for redelivery", key.serverName, key.replicaIdx, s.config.EnqueueTimeout)
+		s.enqueuePending(key, op)
+		return false
+	}
+}
+
+// opModelName returns the model an op concerns, or "" for a MODELS_SNAPSHOT op, which
+// has no ModelVersion since it spans every model on the replica.
+func opModelName(op *pb.ModelOperationMessage) string {
+	return op.GetModelVersion().GetModel()
+}
+
+func (s *Server) setReplicaGeneration(key ServerKey, model string, gen uint64) {
+	s.replicaGenMu.Lock()
+	defer s.replicaGenMu.Unlock()
+	rk := replicaModelKey{ServerKey: key, model: model}
+	if gen > s.replicaGeneration[rk] {
+		s.replicaGeneration[rk] = gen
+	}
+}
+
+// isStaleGeneration reports whether gen predates the last desired-state generation
+// dispatched to key for model, meaning the event it is attached to describes an op
+// that has since been superseded. Staleness is scoped per (key, model), not just key,
+// since the generation counter is global: a replica hosting models A and B must not
+// have an in-flight op for A judged stale merely because a later Sync of B bumped the
+// counter and was delivered first. gen == 0 is treated as unversioned rather than
+// stale - it is the zero value a caller gets for free if it never stamps a generation
+// (e.g. an agent that predates this protocol, or an event raised on the agent's own
+// initiative rather than in response to a dispatched op) - and unconditionally
+// rejecting it would silently drop legitimate events and leave the model stuck in
+// Loading/Unloading. model == "" (a MODELS_SNAPSHOT, which spans every model) is never
+// considered stale for the same reason - it is not scoped to a single model's
+// generation history.
+func (s *Server) isStaleGeneration(key ServerKey, model string, gen uint64) bool {
+	if gen == 0 || model == "" {
+		return false
+	}
+	s.replicaGenMu.Lock()
+	defer s.replicaGenMu.Unlock()
+	return gen < s.replicaGeneration[replicaModelKey{ServerKey: key, model: model}]
+}
+
 func (s *Server) AgentEvent(ctx context.Context, message *pb.ModelEventMessage) (*pb.ModelEventResponse, error) {
 	logger := s.logger.WithField("func", "AgentEvent")
+
+	if err := checkReplicaClaims(ctx, message.ServerName, message.ReplicaIdx, logger); err != nil {
+		return nil, err
+	}
+
+	key := ServerKey{serverName: message.ServerName, replicaIdx: message.ReplicaIdx}
+	if s.isStaleGeneration(key, message.ModelName, message.Generation) {
+		logger.Warnf("Ignoring stale event (generation %d) for model %s from %s:%d", message.Generation, message.ModelName, message.ServerName, message.ReplicaIdx)
+		return &pb.ModelEventResponse{}, nil
+	}
+
 	var state store.ModelReplicaState
 	switch message.Event {
 	case pb.ModelEventMessage_LOADED:
@@ -175,20 +308,114 @@ func (s *Server) AgentEvent(ctx context.Context, message *pb.ModelEventMessage)
 	return &pb.ModelEventResponse{}, nil
 }
 
+// Reconcile snapshots the full desired model set for one replica and sends it as a
+// single ModelSnapshot message so the agent can diff against its local cache and
+// recover models it has no idea it should be running - e.g. after a stream.Send
+// failure that even the pending-queue redelivery on resubscribe missed.
+func (s *Server) Reconcile(serverName string, replicaIdx uint32) error {
+	versions, err := s.store.GetModelsForServer(serverName)
+	if err != nil {
+		return err
+	}
+
+	snapshot := &pb.ModelSnapshot{}
+	for _, v := range versions {
+		snapshot.Models = append(snapshot.Models, &pb.ModelVersion{Model: v.GetModel(), Version: v.GetVersion()})
+	}
+
+	key := ServerKey{serverName: serverName, replicaIdx: replicaIdx}
+	gen := atomic.LoadUint64(&s.generation)
+	op := &pb.ModelOperationMessage{
+		Operation:  pb.ModelOperationMessage_MODELS_SNAPSHOT,
+		Generation: gen,
+		Snapshot:   snapshot,
+	}
+	if !s.enqueueOp(key, op, gen) {
+		return fmt.Errorf("could not deliver reconcile snapshot to %s:%d", serverName, replicaIdx)
+	}
+	return nil
+}
+
+// RunReconcile periodically reconciles every currently-subscribed replica against its
+// full desired model set. It blocks until stop is closed, so callers should run it in
+// its own goroutine alongside ListenForSyncs.
+func (s *Server) RunReconcile(stop <-chan struct{}) {
+	if s.config.ReconcileInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.config.ReconcileInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.reconcileAll()
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (s *Server) reconcileAll() {
+	s.mutext.RLock()
+	keys := make([]ServerKey, 0, len(s.agents))
+	for key := range s.agents {
+		keys = append(keys, key)
+	}
+	s.mutext.RUnlock()
+
+	for _, key := range keys {
+		if err := s.Reconcile(key.serverName, key.replicaIdx); err != nil {
+			s.logger.WithError(err).Warnf("failed to reconcile %s:%d", key.serverName, key.replicaIdx)
+		}
+	}
+}
+
+// checkReplicaClaims rejects a request whose claimed serverName/replicaIdx do not
+// match the TokenClaims bound to ctx by UnaryAuthInterceptor/StreamAuthInterceptor,
+// so a compromised agent cannot impersonate another server's replica - neither to
+// subscribe on its behalf (Subscribe) nor to report model state for it (AgentEvent).
+// A ctx with no bound claims (TokenValidator disabled) is allowed through unchanged,
+// preserving today's behaviour.
+func checkReplicaClaims(ctx context.Context, serverName string, replicaIdx uint32, logger log.FieldLogger) error {
+	claims, ok := claimsFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	if claims.ServerName != serverName {
+		logger.Errorf("token server name %s does not match requested server name %s", claims.ServerName, serverName)
+		return status.Error(codes.PermissionDenied, "token claims do not match requested server name")
+	}
+	if claims.ReplicaIdx != nil && *claims.ReplicaIdx != replicaIdx {
+		logger.Errorf("token replica %s:%d does not match requested replica %s:%d", claims.ServerName, *claims.ReplicaIdx, serverName, replicaIdx)
+		return status.Error(codes.PermissionDenied, "token claims do not match requested replica index")
+	}
+	return nil
+}
+
 func (s *Server) Subscribe(request *pb.AgentSubscribeRequest, stream pb.AgentService_SubscribeServer) error {
 	logger := s.logger.WithField("func", "Subscribe")
 	logger.Infof("Received subscribe request from %s:%d", request.ServerName, request.ReplicaIdx)
 
-	fin := make(chan bool)
+	if err := checkReplicaClaims(stream.Context(), request.ServerName, request.ReplicaIdx, logger); err != nil {
+		return err
+	}
 
-	s.mutext.Lock()
-	s.agents[ServerKey{serverName: request.ServerName, replicaIdx: request.ReplicaIdx}] = &AgentSubscriber{
+	key := ServerKey{serverName: request.ServerName, replicaIdx: request.ReplicaIdx}
+	fin := make(chan bool)
+	sub := &AgentSubscriber{
 		finished: fin,
-		stream:   stream,
+		queue:    make(chan *pb.ModelOperationMessage, subscriberQueueSize),
 	}
+
+	s.mutext.Lock()
+	s.agents[key] = sub
 	s.mutext.Unlock()
 
-	err := s.syncMessage(request, stream)
+	writerDone := make(chan struct{})
+	go s.runSubscriberWriteLoop(key, sub, stream, writerDone)
+	defer close(writerDone)
+
+	err := s.syncMessage(request, sub)
 	if err != nil {
 		return err
 	}
@@ -203,7 +430,7 @@ func (s *Server) Subscribe(request *pb.AgentSubscribeRequest, stream pb.AgentSer
 		case <-ctx.Done():
 			logger.Infof("Client replica %s:%d has disconnected", request.ServerName, request.ReplicaIdx)
 			s.mutext.Lock()
-			delete(s.agents, ServerKey{serverName: request.ServerName, replicaIdx: request.ReplicaIdx})
+			delete(s.agents, key)
 			s.mutext.Unlock()
 			modelsChanged, err := s.store.RemoveServerReplica(request.ServerName, int(request.ReplicaIdx))
 			if err != nil {
@@ -221,7 +448,94 @@ func (s *Server) Subscribe(request *pb.AgentSubscribeRequest, stream pb.AgentSer
 	}
 }
 
-func (s *Server) syncMessage(request *pb.AgentSubscribeRequest, stream pb.AgentService_SubscribeServer) error {
+// runSubscriberWriteLoop is the only goroutine permitted to call stream.Send for this
+// subscriber. It owns sub.queue for the lifetime of the Subscribe call; when done is
+// closed it drains any remaining queued ops into pending before returning, so a
+// replica flap never silently drops an operation.
+func (s *Server) runSubscriberWriteLoop(key ServerKey, sub *AgentSubscriber, stream pb.AgentService_SubscribeServer, done <-chan struct{}) {
+	for {
+		select {
+		case op := <-sub.queue:
+			if err := sendWithRetry(stream, op, s.config.RetryPolicy, done); err != nil {
+				if s.config.RetryPolicy.isRetryable(status.Code(err)) {
+					s.logger.WithError(err).Warnf("stream send failed for %s:%d after retrying, queuing for redelivery", key.serverName, key.replicaIdx)
+					s.enqueuePending(key, op)
+				} else {
+					s.logger.WithError(err).Errorf("stream send failed for %s:%d", key.serverName, key.replicaIdx)
+				}
+			}
+		case <-done:
+			s.drainQueueToPending(key, sub.queue)
+			return
+		}
+	}
+}
+
+// opSender is the part of pb.AgentService_SubscribeServer that sendWithRetry needs,
+// narrowed out so tests can exercise the retry/backoff loop with a fake sender instead
+// of a full grpc.ServerStream.
+type opSender interface {
+	Send(*pb.ModelOperationMessage) error
+}
+
+// sendWithRetry calls stream.Send(op), retrying with exponential backoff (per policy's
+// InitialBackoff/BackoffMultiplier/MaxBackoff) as long as the failure's status code is
+// retryable, up to policy.MaxAttempts attempts total. It returns the last error once
+// attempts are exhausted or a non-retryable error is hit. A nil policy sends exactly
+// once, matching isRetryable's nil-safe "nothing is retryable" behaviour. done lets a
+// subscriber shutting down interrupt a pending backoff sleep rather than delaying
+// Subscribe's cleanup path until the next attempt is due.
+func sendWithRetry(stream opSender, op *pb.ModelOperationMessage, policy *RetryPolicy, done <-chan struct{}) error {
+	attempts := 1
+	var backoff, maxBackoff time.Duration
+	var multiplier float64
+	if policy != nil {
+		if policy.MaxAttempts > 1 {
+			attempts = policy.MaxAttempts
+		}
+		backoff = policy.InitialBackoff
+		maxBackoff = policy.MaxBackoff
+		multiplier = policy.BackoffMultiplier
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		err = stream.Send(op)
+		if err == nil {
+			return nil
+		}
+		if !policy.isRetryable(status.Code(err)) || attempt == attempts-1 {
+			return err
+		}
+		select {
+		case <-time.After(backoff):
+		case <-done:
+			return err
+		}
+		if multiplier > 0 {
+			backoff = time.Duration(float64(backoff) * multiplier)
+		}
+		if maxBackoff > 0 && backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+	return err
+}
+
+// drainQueueToPending moves any ops left in queue to pending so they are resent once
+// the replica resubscribes, rather than being lost when the writer goroutine exits.
+func (s *Server) drainQueueToPending(key ServerKey, queue chan *pb.ModelOperationMessage) {
+	for {
+		select {
+		case op := <-queue:
+			s.enqueuePending(key, op)
+		default:
+			return
+		}
+	}
+}
+
+func (s *Server) syncMessage(request *pb.AgentSubscribeRequest, sub *AgentSubscriber) error {
 	s.mutext.Lock()
 	defer s.mutext.Unlock()
 
@@ -234,5 +548,55 @@ func (s *Server) syncMessage(request *pb.AgentSubscribeRequest, stream pb.AgentS
 	if err != nil {
 		return err
 	}
+	s.drainPendingLocked(ServerKey{serverName: request.ServerName, replicaIdx: request.ReplicaIdx}, sub)
 	return nil
-}
\ No newline at end of file
+}
+
+// enqueuePending records an operation that could not be delivered to a replica so it
+// can be resent once that replica resubscribes, rather than being silently dropped.
+// An op that is already stale by the time it would be queued - i.e. a newer op for the
+// same key has since been dispatched - is dropped instead, so a LOAD_MODEL that failed
+// to send can never sit in pending and be redelivered after a later UNLOAD_MODEL for
+// the same replica has already been applied. Each replica's remaining backlog is
+// capped at config.MaxPendingPerReplica: once full, the oldest pending op is dropped to
+// make room for op, so a replica that is permanently gone (decommissioned, never
+// resubscribing) cannot grow pending without bound.
+func (s *Server) enqueuePending(key ServerKey, op *pb.ModelOperationMessage) {
+	if s.isStaleGeneration(key, opModelName(op), op.Generation) {
+		s.logger.Warnf("dropping stale op (generation %d) for %s:%d instead of queuing for redelivery, a newer generation has already been dispatched", op.Generation, key.serverName, key.replicaIdx)
+		return
+	}
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+	ops := append(s.pending[key], op)
+	if max := s.config.MaxPendingPerReplica; max > 0 && len(ops) > max {
+		s.logger.Warnf("pending queue for %s:%d exceeded %d ops, dropping oldest", key.serverName, key.replicaIdx, max)
+		ops = ops[len(ops)-max:]
+	}
+	s.pending[key] = ops
+}
+
+// drainPendingLocked hands any operations queued for key while it was disconnected
+// back to its (now reconnected) subscriber. Ops are re-checked for staleness here too,
+// not just on the way into pending, in case a newer generation was dispatched and
+// applied to this replica while the older op was sitting in pending - without this,
+// resubscribe could still redeliver a LOAD_MODEL that predates an UNLOAD_MODEL the
+// replica has already acted on.
+func (s *Server) drainPendingLocked(key ServerKey, sub *AgentSubscriber) {
+	s.pendingMu.Lock()
+	ops := s.pending[key]
+	delete(s.pending, key)
+	s.pendingMu.Unlock()
+
+	for _, op := range ops {
+		if s.isStaleGeneration(key, opModelName(op), op.Generation) {
+			s.logger.Warnf("dropping stale pending op (generation %d) for %s:%d on redelivery, a newer generation has already been dispatched", op.Generation, key.serverName, key.replicaIdx)
+			continue
+		}
+		select {
+		case sub.queue <- op:
+		default:
+			s.enqueuePending(key, op)
+		}
+	}
+}
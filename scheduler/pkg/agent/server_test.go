@@ -0,0 +1,293 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/seldonio/seldon-core/scheduler/apis/mlops/agent"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeSender's Send fails with the given retryable status for the first failCount
+// calls, then succeeds.
+type fakeSender struct {
+	failCount int
+	calls     int
+}
+
+func (f *fakeSender) Send(*pb.ModelOperationMessage) error {
+	f.calls++
+	if f.calls <= f.failCount {
+		return status.Error(codes.Unavailable, "unavailable")
+	}
+	return nil
+}
+
+func newTestServer(config *AgentServerConfig) *Server {
+	if config == nil {
+		config = DefaultAgentServerConfig()
+	}
+	return &Server{
+		logger:            log.New(),
+		agents:            make(map[ServerKey]*AgentSubscriber),
+		pending:           make(map[ServerKey][]*pb.ModelOperationMessage),
+		config:            config,
+		replicaGeneration: make(map[replicaModelKey]uint64),
+	}
+}
+
+// TestEnqueueOpBlocksUntilTimeoutThenFallsBackToPending exercises the backpressure
+// path: a full subscriber queue should make enqueueOp block for config.EnqueueTimeout
+// rather than falling back to pending immediately.
+func TestEnqueueOpBlocksUntilTimeoutThenFallsBackToPending(t *testing.T) {
+	config := DefaultAgentServerConfig()
+	config.EnqueueTimeout = 50 * time.Millisecond
+	s := newTestServer(config)
+
+	key := ServerKey{serverName: "server", replicaIdx: 0}
+	sub := &AgentSubscriber{queue: make(chan *pb.ModelOperationMessage, 1)}
+	s.agents[key] = sub
+
+	// Fill the queue so the next enqueueOp has nowhere to go.
+	sub.queue <- &pb.ModelOperationMessage{Operation: pb.ModelOperationMessage_LOAD_MODEL}
+
+	start := time.Now()
+	ok := s.enqueueOp(key, &pb.ModelOperationMessage{Operation: pb.ModelOperationMessage_LOAD_MODEL}, 1)
+	elapsed := time.Since(start)
+
+	if ok {
+		t.Fatal("expected enqueueOp to report failure once the queue stayed full")
+	}
+	if elapsed < config.EnqueueTimeout {
+		t.Fatalf("expected enqueueOp to block for at least %s, only blocked for %s", config.EnqueueTimeout, elapsed)
+	}
+	if got := len(s.pending[key]); got != 1 {
+		t.Fatalf("expected the timed-out op to fall back to pending, got %d pending ops", got)
+	}
+}
+
+// TestIsStaleGenerationTreatsZeroAsUnversioned ensures an event with the Go zero value
+// for Generation (e.g. from an agent that does not populate it) is never rejected as
+// stale, even once the replica has a nonzero last-observed generation.
+func TestIsStaleGenerationTreatsZeroAsUnversioned(t *testing.T) {
+	s := newTestServer(nil)
+	key := ServerKey{serverName: "server", replicaIdx: 0}
+	s.setReplicaGeneration(key, "model-a", 5)
+
+	if s.isStaleGeneration(key, "model-a", 0) {
+		t.Fatal("expected generation 0 to be treated as unversioned, not stale")
+	}
+	if !s.isStaleGeneration(key, "model-a", 4) {
+		t.Fatal("expected generation 4 to be stale once generation 5 has been observed")
+	}
+	if s.isStaleGeneration(key, "model-a", 5) {
+		t.Fatal("expected generation 5 (the latest observed) to not be stale")
+	}
+}
+
+// TestIsStaleGenerationScopedPerModel reproduces the cross-model bug: a replica
+// hosting two models must not have an in-flight op for one model judged stale merely
+// because a later generation was dispatched and observed for an unrelated model on the
+// same replica.
+func TestIsStaleGenerationScopedPerModel(t *testing.T) {
+	s := newTestServer(nil)
+	key := ServerKey{serverName: "server", replicaIdx: 0}
+
+	// model-b's unload (generation 6) is delivered and observed after model-a's load
+	// (generation 5) was dispatched but before it was acknowledged.
+	s.setReplicaGeneration(key, "model-b", 6)
+
+	if s.isStaleGeneration(key, "model-a", 5) {
+		t.Fatal("expected model-a's generation 5 to not be stale due to an unrelated model-b generation bump")
+	}
+	if s.isStaleGeneration(key, "model-b", 6) {
+		t.Fatal("expected model-b's own latest generation to not be stale")
+	}
+}
+
+// TestIsStaleGenerationNeverStaleForSnapshot ensures a MODELS_SNAPSHOT op (identified
+// by the "" model name, since it has no single ModelVersion) is never treated as stale
+// - it is not scoped to any one model's generation history.
+func TestIsStaleGenerationNeverStaleForSnapshot(t *testing.T) {
+	s := newTestServer(nil)
+	key := ServerKey{serverName: "server", replicaIdx: 0}
+	s.setReplicaGeneration(key, "model-a", 5)
+
+	if s.isStaleGeneration(key, "", 1) {
+		t.Fatal("expected a snapshot op (model \"\") to never be treated as stale")
+	}
+}
+
+// TestDrainPendingLockedDropsStaleOps reproduces the redelivery bug: an older
+// LOAD_MODEL queued while disconnected must not be redelivered after a newer
+// UNLOAD_MODEL for the same replica has already been dispatched and applied.
+func TestDrainPendingLockedDropsStaleOps(t *testing.T) {
+	s := newTestServer(nil)
+	key := ServerKey{serverName: "server", replicaIdx: 0}
+
+	// The older op never reached the replica and was parked in pending.
+	s.pending[key] = []*pb.ModelOperationMessage{
+		{Operation: pb.ModelOperationMessage_LOAD_MODEL, ModelVersion: &pb.ModelVersion{Model: "model-a"}, Generation: 1},
+	}
+	// A newer op has since been dispatched and applied for the same model on the same replica.
+	s.setReplicaGeneration(key, "model-a", 5)
+
+	sub := &AgentSubscriber{queue: make(chan *pb.ModelOperationMessage, 10)}
+	s.drainPendingLocked(key, sub)
+
+	select {
+	case op := <-sub.queue:
+		t.Fatalf("expected the stale generation-1 op to be dropped, got redelivered op %+v", op)
+	default:
+	}
+}
+
+// TestEnqueuePendingDropsStaleOp ensures a stale op never even enters pending once a
+// newer generation has already been dispatched for the same replica.
+func TestEnqueuePendingDropsStaleOp(t *testing.T) {
+	s := newTestServer(nil)
+	key := ServerKey{serverName: "server", replicaIdx: 0}
+	s.setReplicaGeneration(key, "model-a", 5)
+
+	s.enqueuePending(key, &pb.ModelOperationMessage{Operation: pb.ModelOperationMessage_LOAD_MODEL, ModelVersion: &pb.ModelVersion{Model: "model-a"}, Generation: 1})
+
+	if got := len(s.pending[key]); got != 0 {
+		t.Fatalf("expected stale op to be dropped rather than queued, got %d pending ops", got)
+	}
+}
+
+// TestSendWithRetryRetriesRetryableErrorsUntilSuccess exercises the bounded-attempt
+// backoff loop: it should retry a retryable failure and succeed once the underlying
+// sender recovers, within MaxAttempts.
+func TestSendWithRetryRetriesRetryableErrorsUntilSuccess(t *testing.T) {
+	policy := &RetryPolicy{
+		MaxAttempts:          5,
+		InitialBackoff:       time.Millisecond,
+		MaxBackoff:           10 * time.Millisecond,
+		BackoffMultiplier:    2.0,
+		RetryableStatusCodes: []codes.Code{codes.Unavailable},
+	}
+	sender := &fakeSender{failCount: 3}
+
+	err := sendWithRetry(sender, &pb.ModelOperationMessage{}, policy, make(chan struct{}))
+	if err != nil {
+		t.Fatalf("expected sendWithRetry to eventually succeed, got %v", err)
+	}
+	if sender.calls != 4 {
+		t.Fatalf("expected 4 attempts (3 failures + 1 success), got %d", sender.calls)
+	}
+}
+
+// TestSendWithRetryGivesUpAfterMaxAttempts ensures the loop is bounded rather than
+// retrying forever.
+func TestSendWithRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	policy := &RetryPolicy{
+		MaxAttempts:          3,
+		InitialBackoff:       time.Millisecond,
+		MaxBackoff:           time.Millisecond,
+		BackoffMultiplier:    2.0,
+		RetryableStatusCodes: []codes.Code{codes.Unavailable},
+	}
+	sender := &fakeSender{failCount: 100}
+
+	err := sendWithRetry(sender, &pb.ModelOperationMessage{}, policy, make(chan struct{}))
+	if err == nil {
+		t.Fatal("expected sendWithRetry to give up and return an error")
+	}
+	if sender.calls != policy.MaxAttempts {
+		t.Fatalf("expected exactly %d attempts, got %d", policy.MaxAttempts, sender.calls)
+	}
+}
+
+// nonRetryableSender always fails with a status code the policy does not consider
+// retryable.
+type nonRetryableSender struct {
+	calls int
+}
+
+func (f *nonRetryableSender) Send(*pb.ModelOperationMessage) error {
+	f.calls++
+	return status.Error(codes.InvalidArgument, "bad request")
+}
+
+// TestSendWithRetryDoesNotRetryNonRetryableErrors ensures a non-retryable failure
+// returns immediately without consuming the backoff budget.
+func TestSendWithRetryDoesNotRetryNonRetryableErrors(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	sender := &nonRetryableSender{}
+
+	err := sendWithRetry(sender, &pb.ModelOperationMessage{}, policy, make(chan struct{}))
+	if err == nil {
+		t.Fatal("expected a non-retryable error to be returned")
+	}
+	if sender.calls != 1 {
+		t.Fatalf("expected exactly 1 attempt for a non-retryable error, got %d", sender.calls)
+	}
+}
+
+// TestEnqueuePendingCapsBacklogPerReplica ensures a replica that never resubscribes
+// cannot grow pending without bound.
+func TestEnqueuePendingCapsBacklogPerReplica(t *testing.T) {
+	config := DefaultAgentServerConfig()
+	config.MaxPendingPerReplica = 3
+	s := newTestServer(config)
+	key := ServerKey{serverName: "server", replicaIdx: 0}
+
+	for i := 0; i < 10; i++ {
+		s.enqueuePending(key, &pb.ModelOperationMessage{Generation: uint64(i)})
+	}
+
+	ops := s.pending[key]
+	if len(ops) != config.MaxPendingPerReplica {
+		t.Fatalf("expected pending backlog capped at %d, got %d", config.MaxPendingPerReplica, len(ops))
+	}
+	// The oldest ops should have been dropped, keeping the most recent ones.
+	if got := ops[len(ops)-1].Generation; got != 9 {
+		t.Fatalf("expected the newest op (generation 9) to survive, got generation %d", got)
+	}
+}
+
+// TestCheckReplicaClaimsAllowsNoClaims ensures a context with no bound claims (i.e.
+// TokenValidator disabled) is let through unchanged, preserving today's behaviour.
+func TestCheckReplicaClaimsAllowsNoClaims(t *testing.T) {
+	if err := checkReplicaClaims(context.Background(), "server", 0, log.New()); err != nil {
+		t.Fatalf("expected no error with no bound claims, got %v", err)
+	}
+}
+
+// TestCheckReplicaClaimsRejectsMismatchedServerName reproduces the impersonation
+// AgentEvent was missing: a token scoped to one server must not authorize acting as
+// another.
+func TestCheckReplicaClaimsRejectsMismatchedServerName(t *testing.T) {
+	ctx := context.WithValue(context.Background(), authClaimsContextKey, TokenClaims{ServerName: "server-a"})
+
+	err := checkReplicaClaims(ctx, "server-b", 0, log.New())
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied for a mismatched server name, got %v", err)
+	}
+}
+
+// TestCheckReplicaClaimsRejectsMismatchedReplicaIdx ensures a token scoped to one
+// specific replica cannot be used to act as a different replica of the same server.
+func TestCheckReplicaClaimsRejectsMismatchedReplicaIdx(t *testing.T) {
+	replicaIdx := uint32(1)
+	ctx := context.WithValue(context.Background(), authClaimsContextKey, TokenClaims{ServerName: "server", ReplicaIdx: &replicaIdx})
+
+	err := checkReplicaClaims(ctx, "server", 2, log.New())
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied for a mismatched replica index, got %v", err)
+	}
+}
+
+// TestCheckReplicaClaimsAllowsServerScopedToken ensures a token scoped to a whole
+// server (ReplicaIdx nil) authorizes acting as any of that server's replicas.
+func TestCheckReplicaClaimsAllowsServerScopedToken(t *testing.T) {
+	ctx := context.WithValue(context.Background(), authClaimsContextKey, TokenClaims{ServerName: "server"})
+
+	if err := checkReplicaClaims(ctx, "server", 2, log.New()); err != nil {
+		t.Fatalf("expected a server-scoped token to authorize any replica, got %v", err)
+	}
+}
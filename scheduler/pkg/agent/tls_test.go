@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// selfSignedCertWithURI builds a minimal self-signed certificate presenting uri as a
+// URI SAN, the way a SPIFFE-issued certificate presents its SPIFFE ID.
+func selfSignedCertWithURI(t *testing.T, uri string) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if uri != "" {
+		parsed, err := url.Parse(uri)
+		if err != nil {
+			t.Fatalf("failed to parse URI %q: %v", uri, err)
+		}
+		tmpl.URIs = []*url.URL{parsed}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	return der
+}
+
+// TestVerifyPeerCertificateAllowsAnyoneWhenAllowlistEmpty ensures an empty
+// AllowedSPIFFEIDs preserves today's behaviour of trusting any certificate signed by
+// CAFile, rather than rejecting everything.
+func TestVerifyPeerCertificateAllowsAnyoneWhenAllowlistEmpty(t *testing.T) {
+	c := &TLSConfig{}
+	cert := selfSignedCertWithURI(t, "spiffe://cluster.local/ns/default/sa/agent")
+
+	if err := c.verifyPeerCertificate([][]byte{cert}, nil); err != nil {
+		t.Fatalf("expected no error with an empty allowlist, got %v", err)
+	}
+}
+
+// TestVerifyPeerCertificateAllowsAllowedSPIFFEID ensures a certificate presenting an
+// allowed SPIFFE ID is accepted.
+func TestVerifyPeerCertificateAllowsAllowedSPIFFEID(t *testing.T) {
+	allowed := "spiffe://cluster.local/ns/default/sa/agent"
+	c := &TLSConfig{AllowedSPIFFEIDs: []string{allowed}}
+	cert := selfSignedCertWithURI(t, allowed)
+
+	if err := c.verifyPeerCertificate([][]byte{cert}, nil); err != nil {
+		t.Fatalf("expected an allowed SPIFFE ID to be accepted, got %v", err)
+	}
+}
+
+// TestVerifyPeerCertificateRejectsUnlistedSPIFFEID ensures a certificate presenting a
+// SPIFFE ID outside the allowlist is rejected, even though it is otherwise a valid
+// certificate signed by the configured CA.
+func TestVerifyPeerCertificateRejectsUnlistedSPIFFEID(t *testing.T) {
+	c := &TLSConfig{AllowedSPIFFEIDs: []string{"spiffe://cluster.local/ns/default/sa/agent"}}
+	cert := selfSignedCertWithURI(t, "spiffe://cluster.local/ns/default/sa/intruder")
+
+	if err := c.verifyPeerCertificate([][]byte{cert}, nil); err == nil {
+		t.Fatal("expected a certificate with an unlisted SPIFFE ID to be rejected")
+	}
+}
+
+// TestVerifyPeerCertificateRejectsMissingSPIFFEID ensures a certificate with no URI
+// SAN at all is rejected once an allowlist is configured.
+func TestVerifyPeerCertificateRejectsMissingSPIFFEID(t *testing.T) {
+	c := &TLSConfig{AllowedSPIFFEIDs: []string{"spiffe://cluster.local/ns/default/sa/agent"}}
+	cert := selfSignedCertWithURI(t, "")
+
+	if err := c.verifyPeerCertificate([][]byte{cert}, nil); err == nil {
+		t.Fatal("expected a certificate with no SPIFFE ID to be rejected once an allowlist is configured")
+	}
+}